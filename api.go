@@ -2,59 +2,124 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"sort"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
 	"github.com/zeeraw/riksbank"
 	"github.com/zeeraw/riksbank/currency"
 	"github.com/zeeraw/riksbank/date"
+	"github.com/zeeraw/rikskurs.se/cache"
+	moneyfmt "github.com/zeeraw/rikskurs.se/currency"
+	"github.com/zeeraw/rikskurs.se/providers"
 )
 
 var (
-	errNeedBaseCurrency          = errors.New("need base currency")
-	errNeedCounterCurrency       = errors.New("need counter currency")
-	errNoCurrencyDataForPeriod   = errors.New("no data for currencies in that period")
-	errNoConversionRateForPeriod = errors.New("no conversion rate for that period")
+	errNeedBaseCurrency        = errors.New("need base currency")
+	errNeedCounterCurrency     = errors.New("need counter currency")
+	errNoCurrencyDataForPeriod = errors.New("no data for currencies in that period")
+	errUnknownProvider         = errors.New("unknown provider")
 )
 
-func rateForDate(ctx context.Context, rb *riksbank.Riksbank, base, counter currency.Currency, date time.Time) (rate float64, err error) {
-	res, err := rb.ExchangeRates(ctx, &riksbank.ExchangeRatesRequest{
-		CurrencyPairs: []currency.Pair{
-			currency.Pair{
-				Base:    base,
-				Counter: counter,
-			},
-		},
-		AggregateMethod: riksbank.Daily,
-		From:            date.AddDate(0, 0, -7),
-		To:              date,
-	})
-	if err != nil {
-		return rate, err
+// pivotCurrencies are tried in order when a direct (or inverse) quote for
+// a pair isn't available anywhere in the chain. SEK is tried first since
+// it's what Riksbank itself publishes against; EUR and USD follow as the
+// other widely quoted anchors.
+var pivotCurrencies = []string{"SEK", "EUR", "USD"}
+
+// legRate resolves a single-hop quote from->to, trying the direct
+// direction first and falling back to the inverse of to->from. The
+// inverse is computed in decimal, not float64, to avoid compounding
+// rounding drift into triangulated cross rates.
+func legRate(ctx context.Context, chain *providers.Chain, from, to currency.Currency, day time.Time) (rate decimal.Decimal, asOf time.Time, source string, inverse bool, ok bool) {
+	rate, asOf, source, err := chain.Rate(ctx, from, to, day)
+	if err == nil {
+		return rate, asOf, source, false, true
+	}
+	rate, asOf, source, err = chain.Rate(ctx, to, from, day)
+	if err != nil || rate.IsZero() {
+		return decimal.Decimal{}, asOf, "", false, false
 	}
-	exchangeRates := riksbank.ExchangeRates{}
-	for _, er := range res.ExchangeRates {
-		if er.Base == base && er.Counter == counter {
-			exchangeRates = append(exchangeRates, er)
+	return decimal.NewFromInt(1).Div(rate), asOf, source, true, true
+}
+
+// resolveCrossRate resolves base/counter as of day, trying a direct quote
+// first, then its inverse, then triangulating through a pivot currency
+// (SEK, then EUR, then USD) when neither leg is available directly. It
+// returns the path taken (e.g. "NOK->SEK->JPY") so callers can audit a
+// synthetic quote via the X-Rate-Path header.
+func resolveCrossRate(ctx context.Context, chain *providers.Chain, base, counter currency.Currency, day time.Time, directOnly bool) (rate decimal.Decimal, asOf time.Time, source, path string, err error) {
+	if rate, asOf, source, inverse, ok := legRate(ctx, chain, base, counter, day); ok {
+		path = fmt.Sprintf("%s->%s", base.String(), counter.String())
+		if inverse {
+			path += " (inverse)"
 		}
+		return rate, asOf, source, path, nil
 	}
-	sort.Slice(exchangeRates, func(i int, j int) bool {
-		return exchangeRates[j].Date.UnixNano() < exchangeRates[i].Date.UnixNano()
-	})
-	if len(exchangeRates) < 1 {
-		return rate, errNoCurrencyDataForPeriod
+	if directOnly {
+		return rate, asOf, source, path, errNoCurrencyDataForPeriod
 	}
-	value := exchangeRates[0].Value
-	if value == nil {
-		return rate, errNoConversionRateForPeriod
+	for _, code := range pivotCurrencies {
+		pivot := currency.Parse(code)
+		if pivot == base || pivot == counter {
+			continue
+		}
+		baseRate, baseAsOf, baseSource, _, ok := legRate(ctx, chain, base, pivot, day)
+		if !ok {
+			continue
+		}
+		counterRate, counterAsOf, counterSource, _, ok := legRate(ctx, chain, counter, pivot, day)
+		if !ok || counterRate.IsZero() {
+			continue
+		}
+		asOf = baseAsOf
+		if counterAsOf.Before(asOf) {
+			asOf = counterAsOf
+		}
+		return baseRate.Div(counterRate), asOf, fmt.Sprintf("%s,%s", baseSource, counterSource), fmt.Sprintf("%s->%s->%s", base.String(), pivot.String(), counter.String()), nil
 	}
-	return *value, nil
+	return rate, asOf, source, path, errNoCurrencyDataForPeriod
+}
+
+// resolveRate wraps resolveCrossRate in the rate cache, keyed by the
+// pinned provider (or "chain" when none is pinned), whether direct_only
+// was requested, plus the pair and date. A pinned provider is resolved
+// against a single-element chain so triangulation still only ever calls
+// that one provider.
+func resolveRate(ctx context.Context, rc *cache.RateCache, chain *providers.Chain, base, counter currency.Currency, day time.Time, pin string, directOnly bool) (rate decimal.Decimal, asOf time.Time, source, path string, hit bool, err error) {
+	effectiveChain := chain
+	keyProvider := "chain"
+	if pin != "" {
+		p := chain.Find(pin)
+		if p == nil {
+			return rate, asOf, source, path, hit, errUnknownProvider
+		}
+		effectiveChain = providers.NewChain(p)
+		keyProvider = pin
+	}
+	if directOnly {
+		keyProvider += "|direct"
+	}
+	key := cache.Key(keyProvider, base.String(), counter.String(), day)
+	entry, hit, err := rc.Rate(ctx, key, day, func(ctx context.Context) (decimal.Decimal, time.Time, string, string, error) {
+		return resolveCrossRate(ctx, effectiveChain, base, counter, day, directOnly)
+	})
+	if err != nil {
+		return rate, asOf, source, path, hit, err
+	}
+	return entry.Rate, entry.AsOf, entry.Source, entry.Path, hit, nil
+}
+
+func parseDirectOnlyParam(r *http.Request) bool {
+	v, _ := strconv.ParseBool(r.URL.Query().Get("direct_only"))
+	return v
 }
 
 func parseDateParam(r *http.Request) (day time.Time, err error) {
@@ -88,17 +153,57 @@ func parseExchangeParams(r *http.Request) (base, counter currency.Currency, day
 	return base, counter, day, nil
 }
 
-func parseValueParams(r *http.Request) (f float64, err error) {
+func parseValueParams(r *http.Request) (value decimal.Decimal, err error) {
 	vars := mux.Vars(r)
 	if vars["value"] == "" {
-		return f, errors.New("no value provided")
+		return value, errors.New("no value provided")
 	}
-	f, err = strconv.ParseFloat(vars["value"], 64)
-	return f, err
+	return decimal.NewFromString(vars["value"])
+}
+
+func parseProviderParam(r *http.Request) string {
+	return r.URL.Query().Get("provider")
+}
+
+// maxPrecisionDigits bounds the ?precision= override. decimal.Decimal has
+// no built-in ceiling, so an unbounded value lets a single request force
+// an allocation and formatted string of arbitrary size; 20 digits is far
+// beyond any real currency's minor unit and still leaves headroom for
+// exotic rounding requests.
+const maxPrecisionDigits = 20
+
+// parseRoundingParams reads the ?rounding= and ?precision= overrides used
+// by exchangeHandler, defaulting rounding to half_up and precision to the
+// target currency's ISO 4217 minor unit digits. precision is clamped to
+// [0, maxPrecisionDigits].
+func parseRoundingParams(r *http.Request, counter currency.Currency) (mode string, digits int32) {
+	mode = strings.ToLower(r.URL.Query().Get("rounding"))
+	if mode == "" {
+		mode = "half_up"
+	}
+	digits = moneyfmt.DigitsFor(counter)
+	if p := r.URL.Query().Get("precision"); p != "" {
+		if n, err := strconv.Atoi(p); err == nil {
+			switch {
+			case n < 0:
+				digits = 0
+			case n > maxPrecisionDigits:
+				digits = maxPrecisionDigits
+			default:
+				digits = int32(n)
+			}
+		}
+	}
+	return mode, digits
 }
 
 func homeHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if negotiateFormat(r) == formatJSON {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(endpoints)
+			return
+		}
 		w.Header().Add("Content-Type", "text/plain")
 		tw := tabwriter.NewWriter(w, 0, 0, 0, ' ', 0)
 		defer tw.Flush()
@@ -111,9 +216,8 @@ func homeHandler() http.HandlerFunc {
 	}
 }
 
-func exchangeHandler(rb *riksbank.Riksbank) http.HandlerFunc {
+func exchangeHandler(chain *providers.Chain, rc *cache.RateCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("Content-Type", "text/plain")
 		value, err := parseValueParams(r)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
@@ -124,35 +228,61 @@ func exchangeHandler(rb *riksbank.Riksbank) http.HandlerFunc {
 			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 			return
 		}
-		rate, err := rateForDate(r.Context(), rb, base, counter, date)
+		rate, asOf, source, path, hit, err := resolveRate(r.Context(), rc, chain, base, counter, date, parseProviderParam(r), parseDirectOnlyParam(r))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		fmt.Fprintf(w, "%f", rate*value)
+		observeRate(base.String(), counter.String(), rate.InexactFloat64())
+		w.Header().Add("X-Rate-Source", source)
+		w.Header().Add("X-Rate-Path", path)
+		w.Header().Add("X-Cache", cacheStatus(hit))
+		mode, digits := parseRoundingParams(r, counter)
+		amount := value.Mul(rate)
+		converted := moneyfmt.Round(amount, mode, digits).StringFixed(digits)
+		writeExchangeRate(w, negotiateFormat(r), exchangeRatePayload{
+			Base:          base.String(),
+			Counter:       counter.String(),
+			Rate:          rate.InexactFloat64(),
+			Value:         &converted,
+			AsOfDate:      asOf.Format("2006-01-02"),
+			Source:        source,
+			Path:          path,
+			RequestedDate: date.Format("2006-01-02"),
+		})
 	}
 }
 
-func exchangeRateHandler(rb *riksbank.Riksbank) http.HandlerFunc {
+func exchangeRateHandler(chain *providers.Chain, rc *cache.RateCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("Content-Type", "text/plain")
 		base, counter, date, err := parseExchangeParams(r)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 			return
 		}
-		rate, err := rateForDate(r.Context(), rb, base, counter, date)
+		rate, asOf, source, path, hit, err := resolveRate(r.Context(), rc, chain, base, counter, date, parseProviderParam(r), parseDirectOnlyParam(r))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		fmt.Fprintf(w, "%f", rate)
+		observeRate(base.String(), counter.String(), rate.InexactFloat64())
+		w.Header().Add("X-Rate-Source", source)
+		w.Header().Add("X-Rate-Path", path)
+		w.Header().Add("X-Cache", cacheStatus(hit))
+		writeExchangeRate(w, negotiateFormat(r), exchangeRatePayload{
+			Base:          base.String(),
+			Counter:       counter.String(),
+			Rate:          rate.InexactFloat64(),
+			AsOfDate:      asOf.Format("2006-01-02"),
+			Source:        source,
+			Path:          path,
+			RequestedDate: date.Format("2006-01-02"),
+		})
 	}
 }
 
 func dayHandler(rb *riksbank.Riksbank) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("Content-Type", "text/plain")
 		day, err := parseDateParam(r)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
@@ -166,6 +296,33 @@ func dayHandler(rb *riksbank.Riksbank) http.HandlerFunc {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		fmt.Fprintf(w, "%v", res.Days[0].IsBankDay)
+		writeBankDay(w, negotiateFormat(r), bankDayPayload{
+			Date:      day.Format("2006-01-02"),
+			IsBankDay: res.Days[0].IsBankDay,
+		})
+	}
+}
+
+func cacheStatus(hit bool) string {
+	if hit {
+		return "HIT"
+	}
+	return "MISS"
+}
+
+// cachePurgeHandler clears the rate cache. It is only wired up when the
+// service is running with -production, since cache purges are an
+// operational tool, not something to expose on an otherwise open API.
+func cachePurgeHandler(rc *cache.RateCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := rc.Purge(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/zeeraw/riksbank/currency"
+	"github.com/zeeraw/rikskurs.se/providers"
+)
+
+// fakeExchanger serves a fixed set of base->counter rates and nothing
+// else, so tests can assemble a chain with exactly the legs a scenario
+// needs.
+type fakeExchanger struct {
+	name  string
+	rates map[currency.Pair]decimal.Decimal
+}
+
+func (f *fakeExchanger) Name() string { return f.name }
+
+func (f *fakeExchanger) Supports(base, counter currency.Currency) bool {
+	_, ok := f.rates[currency.Pair{Base: base, Counter: counter}]
+	return ok
+}
+
+func (f *fakeExchanger) Rate(ctx context.Context, base, counter currency.Currency, day time.Time) (decimal.Decimal, time.Time, error) {
+	rate, ok := f.rates[currency.Pair{Base: base, Counter: counter}]
+	if !ok {
+		return decimal.Decimal{}, day, providers.ErrNoData
+	}
+	return rate, day, nil
+}
+
+func d(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func TestResolveCrossRateDirect(t *testing.T) {
+	sek := currency.Parse("SEK")
+	nok := currency.Parse("NOK")
+	chain := providers.NewChain(&fakeExchanger{
+		name:  "fake",
+		rates: map[currency.Pair]decimal.Decimal{{Base: sek, Counter: nok}: d("1.1")},
+	})
+	rate, _, source, path, err := resolveCrossRate(context.Background(), chain, sek, nok, time.Now(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rate.Equal(d("1.1")) {
+		t.Errorf("rate = %v, want 1.1", rate)
+	}
+	if source != "fake" {
+		t.Errorf("source = %q, want fake", source)
+	}
+	if path != "SEK->NOK" {
+		t.Errorf("path = %q, want SEK->NOK", path)
+	}
+}
+
+func TestResolveCrossRateInverse(t *testing.T) {
+	sek := currency.Parse("SEK")
+	nok := currency.Parse("NOK")
+	chain := providers.NewChain(&fakeExchanger{
+		name:  "fake",
+		rates: map[currency.Pair]decimal.Decimal{{Base: nok, Counter: sek}: d("2")},
+	})
+	rate, _, _, path, err := resolveCrossRate(context.Background(), chain, sek, nok, time.Now(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rate.Equal(d("0.5")) {
+		t.Errorf("rate = %v, want 0.5 (inverse of 2)", rate)
+	}
+	if path != "SEK->NOK (inverse)" {
+		t.Errorf("path = %q, want SEK->NOK (inverse)", path)
+	}
+}
+
+func TestResolveCrossRatePivot(t *testing.T) {
+	nok := currency.Parse("NOK")
+	sek := currency.Parse("SEK")
+	jpy := currency.Parse("JPY")
+	chain := providers.NewChain(&fakeExchanger{
+		name: "fake",
+		rates: map[currency.Pair]decimal.Decimal{
+			{Base: nok, Counter: sek}: d("1"),
+			{Base: jpy, Counter: sek}: d("0.1"),
+		},
+	})
+	rate, _, _, path, err := resolveCrossRate(context.Background(), chain, nok, jpy, time.Now(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := d("1").Div(d("0.1"))
+	if !rate.Equal(want) {
+		t.Errorf("rate = %v, want %v", rate, want)
+	}
+	if path != "NOK->SEK->JPY" {
+		t.Errorf("path = %q, want NOK->SEK->JPY", path)
+	}
+}
+
+func TestResolveCrossRateDirectOnlyFailsWithoutDirectLeg(t *testing.T) {
+	nok := currency.Parse("NOK")
+	sek := currency.Parse("SEK")
+	jpy := currency.Parse("JPY")
+	chain := providers.NewChain(&fakeExchanger{
+		name: "fake",
+		rates: map[currency.Pair]decimal.Decimal{
+			{Base: nok, Counter: sek}: d("1"),
+			{Base: jpy, Counter: sek}: d("0.1"),
+		},
+	})
+	_, _, _, _, err := resolveCrossRate(context.Background(), chain, nok, jpy, time.Now(), true)
+	if err != errNoCurrencyDataForPeriod {
+		t.Fatalf("expected errNoCurrencyDataForPeriod, got %v", err)
+	}
+}
+
+func TestResolveCrossRateSkipsZeroRateLeg(t *testing.T) {
+	nok := currency.Parse("NOK")
+	sek := currency.Parse("SEK")
+	jpy := currency.Parse("JPY")
+	chain := providers.NewChain(&fakeExchanger{
+		name: "fake",
+		rates: map[currency.Pair]decimal.Decimal{
+			{Base: nok, Counter: sek}: d("1"),
+			{Base: jpy, Counter: sek}: d("0"),
+		},
+	})
+	_, _, _, _, err := resolveCrossRate(context.Background(), chain, nok, jpy, time.Now(), false)
+	if err != errNoCurrencyDataForPeriod {
+		t.Fatalf("expected errNoCurrencyDataForPeriod when the only pivot leg has a zero rate, got %v", err)
+	}
+}
+
+func TestParseRoundingParamsClampsPrecision(t *testing.T) {
+	nok := currency.Parse("NOK")
+	cases := []struct {
+		precision string
+		want      int32
+	}{
+		{"", 2},
+		{"5", 5},
+		{"-3", 0},
+		{"100000000", maxPrecisionDigits},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/exchange/1/sek/nok?precision="+c.precision, nil)
+		_, digits := parseRoundingParams(r, nok)
+		if digits != c.want {
+			t.Errorf("precision=%q: digits = %d, want %d", c.precision, digits, c.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/sync/singleflight"
+)
+
+// Fetch resolves a fresh quote on a cache miss. path describes how the
+// quote was derived (e.g. a direct quote, an inverse, or a pivot chain
+// such as "NOK->SEK->JPY") and is cached alongside the rate.
+type Fetch func(ctx context.Context) (rate decimal.Decimal, asOf time.Time, source string, path string, err error)
+
+// BankDayBoundary returns the start of the next bank day strictly after
+// now, used as the TTL for "latest" quotes.
+type BankDayBoundary func(ctx context.Context, now time.Time) (time.Time, error)
+
+// RateCache caches quotes with two TTL regimes: quotes for dates strictly
+// before today never expire, since bank-day rates are immutable once
+// published, while quotes for today expire at the next bank-day boundary
+// so the service re-fetches once a new rate is likely to exist. Cache
+// misses for the same key are coalesced with singleflight so concurrent
+// requests for the same pair/date only trigger one upstream call.
+type RateCache struct {
+	store    Store
+	boundary BankDayBoundary
+	group    singleflight.Group
+}
+
+// NewRateCache wraps store with bank-day aware TTLs. boundary may be nil,
+// in which case "latest" entries use a conservative one hour TTL.
+func NewRateCache(store Store, boundary BankDayBoundary) *RateCache {
+	return &RateCache{store: store, boundary: boundary}
+}
+
+// Key builds the cache key for (provider, base, counter, date).
+func Key(provider, base, counter string, day time.Time) string {
+	return fmt.Sprintf("%s|%s|%s|%s", provider, base, counter, day.Format("2006-01-02"))
+}
+
+// Rate returns the cached entry for key if present and fresh. On a miss it
+// calls fetch exactly once even under concurrent callers, caches the
+// result according to day's TTL regime, and reports hit as false.
+func (c *RateCache) Rate(ctx context.Context, key string, day time.Time, fetch Fetch) (entry Entry, hit bool, err error) {
+	if cached, ok, err := c.store.Get(ctx, key); err != nil {
+		return Entry{}, false, err
+	} else if ok {
+		return cached, true, nil
+	}
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		rate, asOf, source, path, err := fetch(ctx)
+		if err != nil {
+			return Entry{}, err
+		}
+		fresh := Entry{Rate: rate, AsOf: asOf, Source: source, Path: path}
+		ttl, err := c.ttlFor(ctx, day)
+		if err != nil {
+			ttl = time.Hour
+		}
+		if err := c.store.Set(ctx, key, fresh, ttl); err != nil {
+			return fresh, err
+		}
+		return fresh, nil
+	})
+	if err != nil {
+		return Entry{}, false, err
+	}
+	return v.(Entry), false, nil
+}
+
+// ttlFor returns 0 (never expire) for any day strictly before today, and
+// the time remaining until the next bank-day boundary for today or any
+// future day (a future date either has no quote yet, in which case fetch
+// fails and nothing is cached, or it does and should be re-checked like a
+// "latest" quote rather than pinned forever).
+func (c *RateCache) ttlFor(ctx context.Context, day time.Time) (time.Duration, error) {
+	now := time.Now()
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if day.Before(startOfToday) {
+		return 0, nil
+	}
+	if c.boundary == nil {
+		return time.Hour, nil
+	}
+	next, err := c.boundary(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	return time.Until(next), nil
+}
+
+// Purge clears every cached entry.
+func (c *RateCache) Purge(ctx context.Context) error {
+	return c.store.Purge(ctx)
+}
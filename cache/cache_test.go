@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTTLForPastDayNeverExpires(t *testing.T) {
+	rc := NewRateCache(NewMemoryStore(0), nil)
+	past := time.Now().AddDate(0, 0, -1)
+	ttl, err := rc.ttlFor(context.Background(), past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl != 0 {
+		t.Errorf("ttlFor(yesterday) = %v, want 0 (never expire)", ttl)
+	}
+}
+
+func TestTTLForTodayUsesBoundary(t *testing.T) {
+	want := time.Now().Add(2 * time.Hour)
+	rc := NewRateCache(NewMemoryStore(0), func(ctx context.Context, now time.Time) (time.Time, error) {
+		return want, nil
+	})
+	ttl, err := rc.ttlFor(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl <= 0 || ttl > 2*time.Hour {
+		t.Errorf("ttlFor(today) = %v, want a positive duration up to 2h", ttl)
+	}
+}
+
+func TestTTLForFutureDayUsesBoundary(t *testing.T) {
+	want := time.Now().Add(2 * time.Hour)
+	rc := NewRateCache(NewMemoryStore(0), func(ctx context.Context, now time.Time) (time.Time, error) {
+		return want, nil
+	})
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	ttl, err := rc.ttlFor(context.Background(), tomorrow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl <= 0 || ttl > 2*time.Hour {
+		t.Errorf("ttlFor(tomorrow) = %v, want a positive duration up to 2h, not a permanent cache", ttl)
+	}
+}
+
+func TestTTLForNilBoundaryDefaultsToOneHour(t *testing.T) {
+	rc := NewRateCache(NewMemoryStore(0), nil)
+	ttl, err := rc.ttlFor(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl != time.Hour {
+		t.Errorf("ttlFor with no boundary = %v, want 1h", ttl)
+	}
+}
+
+func TestRateCoalescesConcurrentMisses(t *testing.T) {
+	rc := NewRateCache(NewMemoryStore(0), nil)
+	var calls int32
+	const goroutines = 20
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			_, _, err := rc.Rate(context.Background(), "k", time.Now().AddDate(0, 0, -1), func(ctx context.Context) (decimal.Decimal, time.Time, string, string, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+				return decimal.NewFromInt(1), time.Now(), "fake", "", nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("fetch was called %d times under concurrent misses, want exactly 1", got)
+	}
+}
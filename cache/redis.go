@@ -0,0 +1,63 @@
+//go:build redis
+// +build redis
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisStore is an optional Store backend for sharing the rate cache
+// across multiple instances of the service. It is only compiled in with
+// the "redis" build tag, keeping the Redis client out of the default build.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore wraps an existing redis client. Keys are namespaced with
+// prefix so the cache can share a Redis instance with other data.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Get implements Store.
+func (s *RedisStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	raw, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err == redis.Nil {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return Entry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// Set implements Store. A ttl <= 0 is translated to no expiry.
+func (s *RedisStore) Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, raw, ttl).Err()
+}
+
+// Purge implements Store.
+func (s *RedisStore) Purge(ctx context.Context) error {
+	keys, err := s.client.Keys(ctx, s.prefix+"*").Result()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,108 @@
+// Package cache provides a pluggable Store for exchange rate quotes, with
+// a bank-day aware TTL layered on top in RateCache.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Entry is a cached exchange rate quote. Path records how it was derived
+// (direct, inverse, or a pivot chain) for replay via X-Rate-Path. Rate is
+// a decimal.Decimal, matching the Exchanger interface it's sourced from,
+// so cached derived rates don't pick up float rounding drift.
+type Entry struct {
+	Rate   decimal.Decimal
+	AsOf   time.Time
+	Source string
+	Path   string
+}
+
+type item struct {
+	key     string
+	entry   Entry
+	expires time.Time // zero means never expires
+}
+
+// Store persists cached rate entries keyed by an opaque string built with
+// Key (provider, base, counter, date).
+type Store interface {
+	Get(ctx context.Context, key string) (entry Entry, ok bool, err error)
+	Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error
+	Purge(ctx context.Context) error
+}
+
+// MemoryStore is an in-process, LRU-bounded Store. It is the default
+// backend used when no external cache is configured.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// NewMemoryStore builds a MemoryStore holding at most capacity entries.
+// A capacity of 0 means unbounded.
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	it := el.Value.(*item)
+	if !it.expires.IsZero() && time.Now().After(it.expires) {
+		s.order.Remove(el)
+		delete(s.items, key)
+		return Entry{}, false, nil
+	}
+	s.order.MoveToFront(el)
+	return it.entry, true, nil
+}
+
+// Set implements Store. A ttl <= 0 means the entry never expires.
+func (s *MemoryStore) Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	if el, ok := s.items[key]; ok {
+		el.Value = &item{key: key, entry: entry, expires: expires}
+		s.order.MoveToFront(el)
+		return nil
+	}
+	el := s.order.PushFront(&item{key: key, entry: entry, expires: expires})
+	s.items[key] = el
+	if s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		if oldest != nil {
+			s.order.Remove(oldest)
+			delete(s.items, oldest.Value.(*item).key)
+		}
+	}
+	return nil
+}
+
+// Purge implements Store.
+func (s *MemoryStore) Purge(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[string]*list.Element)
+	s.order = list.New()
+	return nil
+}
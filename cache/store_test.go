@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMemoryStoreGetSetRoundTrip(t *testing.T) {
+	s := NewMemoryStore(0)
+	entry := Entry{Rate: decimal.NewFromFloat(1.23), Source: "fake"}
+	if err := s.Set(context.Background(), "k", entry, 0); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := s.Get(context.Background(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected hit")
+	}
+	if !got.Rate.Equal(entry.Rate) || got.Source != entry.Source {
+		t.Errorf("Get = %+v, want %+v", got, entry)
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	s := NewMemoryStore(0)
+	ctx := context.Background()
+	if err := s.Set(ctx, "k", Entry{Rate: decimal.NewFromInt(1)}, 5*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	_, ok, err := s.Get(ctx, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("expected entry to have expired")
+	}
+}
+
+func TestMemoryStoreNeverExpiresWithZeroTTL(t *testing.T) {
+	s := NewMemoryStore(0)
+	ctx := context.Background()
+	if err := s.Set(ctx, "k", Entry{Rate: decimal.NewFromInt(1)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	_, ok, err := s.Get(ctx, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("expected a zero ttl to mean the entry never expires")
+	}
+}
+
+func TestMemoryStoreEvictsOldestOverCapacity(t *testing.T) {
+	s := NewMemoryStore(2)
+	ctx := context.Background()
+	s.Set(ctx, "a", Entry{}, 0)
+	s.Set(ctx, "b", Entry{}, 0)
+	s.Set(ctx, "c", Entry{}, 0)
+	if _, ok, _ := s.Get(ctx, "a"); ok {
+		t.Error("expected oldest entry 'a' to be evicted once capacity was exceeded")
+	}
+	if _, ok, _ := s.Get(ctx, "c"); !ok {
+		t.Error("expected most recently set entry 'c' to survive")
+	}
+}
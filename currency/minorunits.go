@@ -0,0 +1,73 @@
+// Package currency provides ISO 4217 minor-unit rounding rules and
+// decimal formatting helpers on top of github.com/zeeraw/riksbank/currency.
+package currency
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+	rkcurrency "github.com/zeeraw/riksbank/currency"
+)
+
+// MinorUnits maps an ISO 4217 currency code to the number of minor unit
+// digits conventionally used when rounding amounts in that currency.
+// Currencies not listed here use the ISO 4217 default of 2.
+var MinorUnits = map[string]int32{
+	"BHD": 3,
+	"BIF": 0,
+	"CLF": 4,
+	"DJF": 0,
+	"GNF": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KMF": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"PYG": 0,
+	"RWF": 0,
+	"TND": 3,
+	"UGX": 0,
+	"UYI": 0,
+	"VND": 0,
+	"VUV": 0,
+	"XAF": 0,
+	"XAG": 0,
+	"XAU": 0,
+	"XOF": 0,
+	"XPF": 0,
+	// Crypto assets have no ISO 4217 entry; BTC is conventionally quoted
+	// to satoshi precision.
+	"BTC": 8,
+	"ETH": 18,
+}
+
+// DigitsFor returns the minor unit digit count for code, defaulting to 2
+// (the ISO 4217 default) for currencies not present in MinorUnits.
+func DigitsFor(code rkcurrency.Currency) int32 {
+	if digits, ok := MinorUnits[strings.ToUpper(code.String())]; ok {
+		return digits
+	}
+	return 2
+}
+
+// Round applies a named rounding rule to amount at the given number of
+// decimal digits. Unrecognised modes fall back to half_up, matching the
+// rounding most callers expect from everyday currency conversion.
+func Round(amount decimal.Decimal, mode string, digits int32) decimal.Decimal {
+	switch mode {
+	case "half_even":
+		return amount.RoundBank(digits)
+	case "down", "truncate":
+		return amount.Truncate(digits)
+	default:
+		return amount.Round(digits)
+	}
+}
+
+// Format rounds amount to the minor-unit precision of code using half_up
+// rounding and renders it as a fixed-point string.
+func Format(amount decimal.Decimal, code rkcurrency.Currency) string {
+	digits := DigitsFor(code)
+	return amount.Round(digits).StringFixed(digits)
+}
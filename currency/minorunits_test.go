@@ -0,0 +1,46 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	rkcurrency "github.com/zeeraw/riksbank/currency"
+)
+
+func TestDigitsFor(t *testing.T) {
+	cases := []struct {
+		code string
+		want int32
+	}{
+		{"JPY", 0},
+		{"BHD", 3},
+		{"SEK", 2},
+		{"XYZ", 2},
+	}
+	for _, c := range cases {
+		got := DigitsFor(rkcurrency.Parse(c.code))
+		if got != c.want {
+			t.Errorf("DigitsFor(%s) = %d, want %d", c.code, got, c.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	amount := decimal.RequireFromString("2.345")
+	cases := []struct {
+		mode string
+		want string
+	}{
+		{"half_up", "2.35"},
+		{"half_even", "2.34"},
+		{"down", "2.34"},
+		{"truncate", "2.34"},
+		{"unrecognised", "2.35"},
+	}
+	for _, c := range cases {
+		got := Round(amount, c.mode, 2).StringFixed(2)
+		if got != c.want {
+			t.Errorf("Round(2.345, %q, 2) = %s, want %s", c.mode, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// outputFormat is a negotiated response representation for a handler.
+type outputFormat string
+
+const (
+	formatPlain    outputFormat = "plain"
+	formatJSON     outputFormat = "json"
+	formatCSV      outputFormat = "csv"
+	formatColumnar outputFormat = "columnar"
+)
+
+// negotiateFormat picks a response format from the `?format=` override, or
+// failing that the request's Accept header, defaulting to plain text to
+// preserve the historical behaviour of these endpoints.
+func negotiateFormat(r *http.Request) outputFormat {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "json":
+		return formatJSON
+	case "csv":
+		return formatCSV
+	case "columnar":
+		return formatColumnar
+	case "text", "plain", "text/plain":
+		return formatPlain
+	}
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/json"):
+		return formatJSON
+	case strings.Contains(accept, "text/csv"):
+		return formatCSV
+	default:
+		return formatPlain
+	}
+}
+
+// exchangeRatePayload is the structured representation of a quote, shared
+// by the JSON and CSV encodings of exchangeHandler and exchangeRateHandler.
+type exchangeRatePayload struct {
+	Base          string  `json:"base"`
+	Counter       string  `json:"counter"`
+	Rate          float64 `json:"rate"`
+	Value         *string `json:"value,omitempty"`
+	AsOfDate      string  `json:"as_of_date"`
+	Source        string  `json:"source"`
+	Path          string  `json:"path"`
+	RequestedDate string  `json:"requested_date"`
+}
+
+func writeExchangeRate(w http.ResponseWriter, format outputFormat, p exchangeRatePayload) {
+	switch format {
+	case formatJSON:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	case formatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"base", "counter", "rate", "value", "as_of_date", "source", "path", "requested_date"})
+		value := ""
+		if p.Value != nil {
+			value = *p.Value
+		}
+		cw.Write([]string{
+			p.Base,
+			p.Counter,
+			strconv.FormatFloat(p.Rate, 'f', -1, 64),
+			value,
+			p.AsOfDate,
+			p.Source,
+			p.Path,
+			p.RequestedDate,
+		})
+		cw.Flush()
+	default:
+		w.Header().Set("Content-Type", "text/plain")
+		if p.Value != nil {
+			w.Write([]byte(*p.Value))
+			return
+		}
+		fmt.Fprintf(w, "%f", p.Rate)
+	}
+}
+
+// bankDayPayload is the structured representation of a bank-day check.
+type bankDayPayload struct {
+	Date      string `json:"date"`
+	IsBankDay bool   `json:"is_bank_day"`
+}
+
+func writeBankDay(w http.ResponseWriter, format outputFormat, p bankDayPayload) {
+	switch format {
+	case formatJSON:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	case formatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"date", "is_bank_day"})
+		cw.Write([]string{p.Date, strconv.FormatBool(p.IsBankDay)})
+		cw.Flush()
+	default:
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strconv.FormatBool(p.IsBankDay)))
+	}
+}
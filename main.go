@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/zeeraw/riksbank"
+	"github.com/zeeraw/rikskurs.se/cache"
+	"github.com/zeeraw/rikskurs.se/providers"
 )
 
 var (
@@ -21,26 +27,102 @@ var (
 		{"convert currency at the exchange rate on a specific date", "/exchange/{value}/{base}/{counter}/{date}", "/exchange/1200.5/sek/nok/2019-01-01"},
 		{"check if the current date is a bank day", "/bankday", "/bankday"},
 		{"check if a specific date is a bank day", "/bankday/{date}", "/bankday/2019-01-01"},
+		{"exchange rate time series for a currency pair", "/exchange/series/{base}/{counter}/{from}/{to}", "/exchange/series/sek/nok/2019-01-01/2019-02-01"},
+		{"bank day time series", "/bankday/series/{from}/{to}", "/bankday/series/2019-01-01/2019-02-01"},
+		{"prometheus metrics for request volume and last observed rates", "/metrics", "/metrics"},
+		{"purge the rate cache (only available with -production)", "/admin/cache/purge", "/admin/cache/purge"},
 	}
 )
 
+// chainConfig controls which providers are wired into the fallback chain
+// and the order they're tried in. It is parsed from flags and environment
+// variables in main() so deployments can add or reorder providers (and
+// supply API keys) without a rebuild.
+type chainConfig struct {
+	order          string
+	openExchangeID string
+	timeout        time.Duration
+}
+
+func buildChain(rb *riksbank.Riksbank, cfg chainConfig) *providers.Chain {
+	frankfurter := providers.NewFrankfurterProvider()
+	openExchangeRates := providers.NewOpenExchangeRatesProvider(cfg.openExchangeID)
+	if cfg.timeout > 0 {
+		frankfurter.Client = &http.Client{Timeout: cfg.timeout}
+		openExchangeRates.Client = &http.Client{Timeout: cfg.timeout}
+	}
+	available := map[string]providers.Exchanger{
+		"riksbank":          providers.NewRiksbankProvider(rb),
+		"frankfurter":       frankfurter,
+		"openexchangerates": openExchangeRates,
+	}
+	var chain []providers.Exchanger
+	for _, name := range strings.Split(cfg.order, ",") {
+		name = strings.TrimSpace(name)
+		if p, ok := available[name]; ok {
+			chain = append(chain, p)
+		}
+	}
+	return providers.NewChain(chain...)
+}
+
+// nextBankDayBoundary returns a cache.BankDayBoundary that asks Riksbank
+// for the next bank day after now, so "latest" rate cache entries expire
+// exactly when a new quote could plausibly exist.
+func nextBankDayBoundary(rb *riksbank.Riksbank) cache.BankDayBoundary {
+	return func(ctx context.Context, now time.Time) (time.Time, error) {
+		loc, err := time.LoadLocation("Europe/Stockholm")
+		if err != nil {
+			loc = time.UTC
+		}
+		for offset := 1; offset <= 14; offset++ {
+			day := now.AddDate(0, 0, offset)
+			res, err := rb.Days(ctx, &riksbank.DaysRequest{From: day, To: day})
+			if err != nil {
+				return time.Time{}, err
+			}
+			if len(res.Days) > 0 && res.Days[0].IsBankDay {
+				return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc), nil
+			}
+		}
+		return now.Add(24 * time.Hour), nil
+	}
+}
+
 func main() {
 	rb := riksbank.New(riksbank.Config{})
 	r := mux.NewRouter()
 
 	var production bool
 	flag.BoolVar(&production, "production", false, "use this flag to run the application in production mode")
+
+	cfg := chainConfig{}
+	flag.StringVar(&cfg.order, "providers", envOr("RATE_PROVIDERS", "riksbank,frankfurter,openexchangerates"), "comma separated provider chain order")
+	flag.StringVar(&cfg.openExchangeID, "openexchangerates-app-id", os.Getenv("OPENEXCHANGERATES_APP_ID"), "app id for the openexchangerates.org API")
+	flag.DurationVar(&cfg.timeout, "provider-timeout", 5*time.Second, "timeout for a single frankfurter or openexchangerates request (not applied to riksbank)")
+
+	var cacheCapacity int
+	flag.IntVar(&cacheCapacity, "cache-capacity", 10000, "maximum number of rate cache entries held in memory")
 	flag.Parse()
 
+	chain := buildChain(rb, cfg)
+	rc := cache.NewRateCache(cache.NewMemoryStore(cacheCapacity), nextBankDayBoundary(rb))
+
 	r.HandleFunc(`/`, homeHandler())
-	r.HandleFunc(`/exchange/rate/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}/{date}`, exchangeRateHandler(rb))
-	r.HandleFunc(`/exchange/rate/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}`, exchangeRateHandler(rb))
-	r.HandleFunc(`/exchange/{value:\d+.\d+}/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}/{date}`, exchangeHandler(rb))
-	r.HandleFunc(`/exchange/{value:\d+}/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}/{date}`, exchangeHandler(rb))
-	r.HandleFunc(`/exchange/{value:\d+.\d+}/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}`, exchangeHandler(rb))
-	r.HandleFunc(`/exchange/{value:\d+}/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}`, exchangeHandler(rb))
+	r.HandleFunc(`/exchange/rate/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}/{date}`, exchangeRateHandler(chain, rc))
+	r.HandleFunc(`/exchange/rate/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}`, exchangeRateHandler(chain, rc))
+	r.HandleFunc(`/exchange/{value:\d+.\d+}/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}/{date}`, exchangeHandler(chain, rc))
+	r.HandleFunc(`/exchange/{value:\d+}/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}/{date}`, exchangeHandler(chain, rc))
+	r.HandleFunc(`/exchange/{value:\d+.\d+}/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}`, exchangeHandler(chain, rc))
+	r.HandleFunc(`/exchange/{value:\d+}/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}`, exchangeHandler(chain, rc))
 	r.HandleFunc(`/bankday/{date}`, dayHandler(rb))
 	r.HandleFunc(`/bankday`, dayHandler(rb))
+	r.HandleFunc(`/exchange/series/{base:[a-zA-Z]{3}}/{counter:[a-zA-Z]{3}}/{from}/{to}`, seriesHandler(chain, rc))
+	r.HandleFunc(`/bankday/series/{from}/{to}`, bankdaySeriesHandler(rb))
+	r.HandleFunc(`/metrics`, metricsHandler())
+	if production {
+		r.HandleFunc(`/admin/cache/purge`, cachePurgeHandler(rc))
+	}
 
 	server := &http.Server{
 		Handler: r,
@@ -48,3 +130,10 @@ func main() {
 	}
 	log.Fatalln(server.ListenAndServe())
 }
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rikskurs_requests_total",
+		Help: "Number of exchange rate requests served, by currency pair.",
+	}, []string{"base", "counter"})
+
+	lastRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rikskurs_last_rate",
+		Help: "Last observed exchange rate for a currency pair.",
+	}, []string{"base", "counter"})
+)
+
+// observeRate records a served rate so it shows up in /metrics.
+func observeRate(base, counter string, rate float64) {
+	requestsTotal.WithLabelValues(base, counter).Inc()
+	lastRate.WithLabelValues(base, counter).Set(rate)
+}
+
+func metricsHandler() http.HandlerFunc {
+	return promhttp.Handler().ServeHTTP
+}
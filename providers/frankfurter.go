@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/zeeraw/riksbank/currency"
+)
+
+// frankfurterDateFormat is the YYYY-MM-DD layout frankfurter.app expects
+// and returns its quote dates in.
+const frankfurterDateFormat = "2006-01-02"
+
+// frankfurterCurrencies lists the currencies frankfurter.app quotes,
+// mirroring the ECB's published reference rates. It does not include
+// crypto or currencies the ECB doesn't track.
+var frankfurterCurrencies = map[string]bool{
+	"AUD": true, "BGN": true, "BRL": true, "CAD": true, "CHF": true, "CNY": true,
+	"CZK": true, "DKK": true, "EUR": true, "GBP": true, "HKD": true, "HUF": true,
+	"IDR": true, "ILS": true, "INR": true, "ISK": true, "JPY": true, "KRW": true,
+	"MXN": true, "MYR": true, "NOK": true, "NZD": true, "PHP": true, "PLN": true,
+	"RON": true, "SEK": true, "SGD": true, "THB": true, "TRY": true, "USD": true,
+	"ZAR": true,
+}
+
+// FrankfurterProvider sources ECB-derived reference rates from
+// frankfurter.app. It has no API key requirement but, like the ECB feed
+// it mirrors, only publishes rates for bank days.
+type FrankfurterProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewFrankfurterProvider builds a FrankfurterProvider pointed at the
+// public frankfurter.app API.
+func NewFrankfurterProvider() *FrankfurterProvider {
+	return &FrankfurterProvider{
+		BaseURL: "https://api.frankfurter.app",
+		Client:  http.DefaultClient,
+	}
+}
+
+// Name implements Exchanger.
+func (p *FrankfurterProvider) Name() string {
+	return "frankfurter"
+}
+
+// Supports implements Exchanger. Frankfurter covers the major and most
+// minor floating currencies the ECB publishes against; it does not cover
+// crypto or heavily pegged exotics.
+func (p *FrankfurterProvider) Supports(base, counter currency.Currency) bool {
+	return frankfurterCurrencies[strings.ToUpper(base.String())] && frankfurterCurrencies[strings.ToUpper(counter.String())]
+}
+
+type frankfurterResponse struct {
+	Amount float64            `json:"amount"`
+	Base   string             `json:"base"`
+	Date   string             `json:"date"`
+	Rates  map[string]float64 `json:"rates"`
+}
+
+// Rate implements Exchanger.
+func (p *FrankfurterProvider) Rate(ctx context.Context, base, counter currency.Currency, day time.Time) (rate decimal.Decimal, asOf time.Time, err error) {
+	url := fmt.Sprintf("%s/%s?from=%s&to=%s", p.BaseURL, day.Format(frankfurterDateFormat), strings.ToUpper(base.String()), strings.ToUpper(counter.String()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return rate, asOf, err
+	}
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return rate, asOf, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return rate, asOf, ErrNoData
+	}
+	var payload frankfurterResponse
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return rate, asOf, err
+	}
+	value, ok := payload.Rates[strings.ToUpper(counter.String())]
+	if !ok {
+		return rate, asOf, ErrNoData
+	}
+	asOf, err = time.Parse(frankfurterDateFormat, payload.Date)
+	if err != nil {
+		return rate, asOf, err
+	}
+	return decimal.NewFromFloat(value), asOf, nil
+}
@@ -0,0 +1,27 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/zeeraw/riksbank/currency"
+)
+
+func TestFrankfurterProviderSupports(t *testing.T) {
+	p := NewFrankfurterProvider()
+	cases := []struct {
+		base, counter string
+		want          bool
+	}{
+		{"EUR", "SEK", true},
+		{"USD", "JPY", true},
+		{"SEK", "BTC", false},
+		{"BTC", "EUR", false},
+		{"BTC", "ETH", false},
+	}
+	for _, c := range cases {
+		got := p.Supports(currency.Parse(c.base), currency.Parse(c.counter))
+		if got != c.want {
+			t.Errorf("Supports(%s, %s) = %v, want %v", c.base, c.counter, got, c.want)
+		}
+	}
+}
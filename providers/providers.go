@@ -0,0 +1,79 @@
+// Package providers defines a pluggable interface for sourcing exchange
+// rates from one or more upstream services, with automatic fallback when
+// a provider has no data for a requested pair or date.
+package providers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/zeeraw/riksbank/currency"
+)
+
+// ErrNoData is returned by an Exchanger when it has no rate for the
+// requested currency pair on or before the requested day.
+var ErrNoData = errors.New("providers: no data for currency pair in that period")
+
+// Exchanger sources exchange rates for currency pairs from a single
+// upstream service.
+type Exchanger interface {
+	// Name identifies the provider, e.g. for the X-Rate-Source header.
+	Name() string
+	// Supports reports whether the provider can plausibly serve the pair.
+	Supports(base, counter currency.Currency) bool
+	// Rate returns the exchange rate for base/counter as of day, along
+	// with the actual date the quote applies to (providers may return
+	// the previous bank day). It returns ErrNoData when no rate exists.
+	// Rate is a decimal.Decimal, not a float64, so derived rates
+	// (inversion, pivot triangulation) can be computed exactly.
+	Rate(ctx context.Context, base, counter currency.Currency, day time.Time) (rate decimal.Decimal, asOf time.Time, err error)
+}
+
+// Chain tries a sequence of Exchangers in order, falling back to the
+// next provider when one returns ErrNoData or any other error.
+type Chain struct {
+	providers []Exchanger
+}
+
+// NewChain builds a Chain that tries providers in the given order.
+func NewChain(providers ...Exchanger) *Chain {
+	return &Chain{providers: providers}
+}
+
+// Providers returns the chain's providers in declared order.
+func (c *Chain) Providers() []Exchanger {
+	return c.providers
+}
+
+// Find returns the provider with the given name, or nil if none matches.
+func (c *Chain) Find(name string) Exchanger {
+	for _, p := range c.providers {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Rate tries each provider in order and returns the first successful
+// quote along with the name of the provider that served it.
+func (c *Chain) Rate(ctx context.Context, base, counter currency.Currency, day time.Time) (rate decimal.Decimal, asOf time.Time, source string, err error) {
+	if len(c.providers) == 0 {
+		return rate, asOf, source, ErrNoData
+	}
+	for _, p := range c.providers {
+		if !p.Supports(base, counter) {
+			continue
+		}
+		rate, asOf, err = p.Rate(ctx, base, counter, day)
+		if err == nil {
+			return rate, asOf, p.Name(), nil
+		}
+	}
+	if err == nil {
+		err = ErrNoData
+	}
+	return decimal.Decimal{}, asOf, "", err
+}
@@ -0,0 +1,28 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/zeeraw/riksbank/currency"
+)
+
+func TestRiksbankProviderSupports(t *testing.T) {
+	p := NewRiksbankProvider(nil)
+	cases := []struct {
+		base, counter string
+		want          bool
+	}{
+		{"SEK", "NOK", true},
+		{"NOK", "SEK", true},
+		{"SEK", "USD", true},
+		{"SEK", "BTC", false},
+		{"NOK", "USD", false},
+		{"BTC", "SEK", false},
+	}
+	for _, c := range cases {
+		got := p.Supports(currency.Parse(c.base), currency.Parse(c.counter))
+		if got != c.want {
+			t.Errorf("Supports(%s, %s) = %v, want %v", c.base, c.counter, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/zeeraw/riksbank"
+	"github.com/zeeraw/riksbank/currency"
+	"github.com/zeeraw/riksbank/date"
+	"github.com/zeeraw/rikskurs.se/cache"
+	"github.com/zeeraw/rikskurs.se/providers"
+)
+
+// seriesPoint is one observation in a time series. Value is nil when the
+// underlying data has no observation for Date and the requested fill mode
+// leaves the gap unfilled.
+type seriesPoint struct {
+	Date  time.Time
+	Value *float64
+}
+
+func parseRangeParams(r *http.Request) (from, to time.Time, err error) {
+	vars := mux.Vars(r)
+	from, err = date.Parse(vars["from"])
+	if err != nil {
+		return from, to, err
+	}
+	to, err = date.Parse(vars["to"])
+	if err != nil {
+		return from, to, err
+	}
+	return from, to, nil
+}
+
+func parseAggParam(r *http.Request) riksbank.AggregateMethod {
+	switch strings.ToLower(r.URL.Query().Get("agg")) {
+	case "weekly":
+		return riksbank.Weekly
+	case "monthly":
+		return riksbank.Monthly
+	default:
+		return riksbank.Daily
+	}
+}
+
+func parseFillParam(r *http.Request) string {
+	switch strings.ToLower(r.URL.Query().Get("fill")) {
+	case "none":
+		return "none"
+	case "interp":
+		return "interp"
+	default:
+		return "last"
+	}
+}
+
+// fillSeries applies the requested fill strategy to gaps (nil values) in a
+// series already sorted by Date ascending.
+func fillSeries(points []seriesPoint, mode string) []seriesPoint {
+	if mode == "none" {
+		return points
+	}
+	filled := make([]seriesPoint, len(points))
+	copy(filled, points)
+	switch mode {
+	case "last":
+		var last *float64
+		for i, p := range filled {
+			if p.Value != nil {
+				last = p.Value
+				continue
+			}
+			filled[i].Value = last
+		}
+	case "interp":
+		i := 0
+		for i < len(filled) {
+			if filled[i].Value != nil {
+				i++
+				continue
+			}
+			start := i - 1
+			end := i
+			for end < len(filled) && filled[end].Value == nil {
+				end++
+			}
+			if start < 0 || end >= len(filled) {
+				i = end + 1
+				continue
+			}
+			lo := *filled[start].Value
+			hi := *filled[end].Value
+			span := float64(end - start)
+			for j := start + 1; j < end; j++ {
+				step := float64(j-start) / span
+				v := lo + (hi-lo)*step
+				filled[j].Value = &v
+			}
+			i = end + 1
+		}
+	}
+	return filled
+}
+
+// seriesDates steps daily from from to to (inclusive), giving the set of
+// dates seriesHandler resolves a rate for before any aggregation is
+// applied.
+func seriesDates(from, to time.Time) []time.Time {
+	var dates []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+// bucketKey groups a date into the period aggregateSeries should collapse
+// it into. Weekly buckets use the ISO week so they don't drift across
+// year boundaries; monthly buckets use the calendar month so they're
+// immune to AddDate's short-month rollover.
+func bucketKey(day time.Time, agg riksbank.AggregateMethod) string {
+	switch agg {
+	case riksbank.Weekly:
+		year, week := day.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case riksbank.Monthly:
+		return fmt.Sprintf("%d-%02d", day.Year(), day.Month())
+	default:
+		return day.Format("2006-01-02")
+	}
+}
+
+// aggregateSeries collapses a daily series (sorted by Date ascending)
+// into one point per week or month, keeping the period's last date present
+// in points as the point's Date and the last non-nil observation in the
+// period as its Value. Daily is a no-op, returning points unchanged.
+func aggregateSeries(points []seriesPoint, agg riksbank.AggregateMethod) []seriesPoint {
+	if agg == riksbank.Daily {
+		return points
+	}
+	var out []seriesPoint
+	var key string
+	for _, p := range points {
+		k := bucketKey(p.Date, agg)
+		if k != key || len(out) == 0 {
+			out = append(out, seriesPoint{Date: p.Date})
+			key = k
+		}
+		i := len(out) - 1
+		out[i].Date = p.Date
+		if p.Value != nil {
+			out[i].Value = p.Value
+		}
+	}
+	return out
+}
+
+// seriesHandler resolves a time series one date at a time through the
+// provider chain and rate cache, so it benefits from the same fallback,
+// caching and cross-rate triangulation as the point-in-time endpoints.
+// Dates the chain can't resolve are left as gaps for fillSeries to
+// handle. Weekly/monthly aggregation is applied in this handler afterward,
+// since the chain only resolves one date at a time.
+func seriesHandler(chain *providers.Chain, rc *cache.RateCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		base := currency.Parse(vars["base"])
+		counter := currency.Parse(vars["counter"])
+		from, to, err := parseRangeParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		directOnly := parseDirectOnlyParam(r)
+		dates := seriesDates(from, to)
+		points := make([]seriesPoint, len(dates))
+		for i, day := range dates {
+			points[i].Date = day
+			rate, _, _, _, _, err := resolveRate(r.Context(), rc, chain, base, counter, day, "", directOnly)
+			if err != nil {
+				continue
+			}
+			value := rate.InexactFloat64()
+			points[i].Value = &value
+		}
+		points = aggregateSeries(points, parseAggParam(r))
+		points = fillSeries(points, parseFillParam(r))
+		writeSeries(w, negotiateFormat(r), base.String(), counter.String(), points)
+	}
+}
+
+func bankdaySeriesHandler(rb *riksbank.Riksbank) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := parseRangeParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		res, err := rb.Days(r.Context(), &riksbank.DaysRequest{
+			From: from,
+			To:   to,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeBankDaySeries(w, negotiateFormat(r), res.Days)
+	}
+}
+
+func writeSeries(w http.ResponseWriter, format outputFormat, base, counter string, points []seriesPoint) {
+	switch format {
+	case formatJSON:
+		w.Header().Set("Content-Type", "application/json")
+		type jsonPoint struct {
+			Date  string   `json:"date"`
+			Value *float64 `json:"value"`
+		}
+		out := make([]jsonPoint, len(points))
+		for i, p := range points {
+			out[i] = jsonPoint{Date: p.Date.Format("2006-01-02"), Value: p.Value}
+		}
+		json.NewEncoder(w).Encode(out)
+	case formatColumnar:
+		w.Header().Set("Content-Type", "application/json")
+		dates := make([]string, len(points))
+		values := make([]*float64, len(points))
+		for i, p := range points {
+			dates[i] = p.Date.Format("2006-01-02")
+			values[i] = p.Value
+		}
+		json.NewEncoder(w).Encode(struct {
+			Base    string     `json:"base"`
+			Counter string     `json:"counter"`
+			Dates   []string   `json:"dates"`
+			Values  []*float64 `json:"values"`
+		}{base, counter, dates, values})
+	default:
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"date", "value"})
+		for _, p := range points {
+			value := ""
+			if p.Value != nil {
+				value = strconv.FormatFloat(*p.Value, 'f', -1, 64)
+			}
+			cw.Write([]string{p.Date.Format("2006-01-02"), value})
+		}
+		cw.Flush()
+	}
+}
+
+func writeBankDaySeries(w http.ResponseWriter, format outputFormat, days []riksbank.Day) {
+	switch format {
+	case formatJSON, formatColumnar:
+		w.Header().Set("Content-Type", "application/json")
+		type jsonDay struct {
+			Date      string `json:"date"`
+			IsBankDay bool   `json:"is_bank_day"`
+		}
+		out := make([]jsonDay, len(days))
+		for i, d := range days {
+			out[i] = jsonDay{Date: d.Date.Format("2006-01-02"), IsBankDay: d.IsBankDay}
+		}
+		json.NewEncoder(w).Encode(out)
+	default:
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"date", "is_bank_day"})
+		for _, d := range days {
+			cw.Write([]string{d.Date.Format("2006-01-02"), fmt.Sprintf("%v", d.IsBankDay)})
+		}
+		cw.Flush()
+	}
+}
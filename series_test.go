@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zeeraw/riksbank"
+)
+
+func pt(day int, value *float64) seriesPoint {
+	return seriesPoint{Date: time.Date(2019, time.January, day, 0, 0, 0, 0, time.UTC), Value: value}
+}
+
+func f(v float64) *float64 { return &v }
+
+func TestFillSeriesNone(t *testing.T) {
+	in := []seriesPoint{pt(1, f(1)), pt(2, nil), pt(3, f(3))}
+	out := fillSeries(in, "none")
+	if out[1].Value != nil {
+		t.Fatalf("none mode should leave gaps unfilled, got %v", out[1].Value)
+	}
+}
+
+func TestFillSeriesLast(t *testing.T) {
+	in := []seriesPoint{pt(1, f(1)), pt(2, nil), pt(3, nil), pt(4, f(4))}
+	out := fillSeries(in, "last")
+	if out[1].Value == nil || *out[1].Value != 1 {
+		t.Fatalf("expected day 2 to carry forward 1, got %v", out[1].Value)
+	}
+	if out[2].Value == nil || *out[2].Value != 1 {
+		t.Fatalf("expected day 3 to carry forward 1, got %v", out[2].Value)
+	}
+}
+
+func TestFillSeriesLastLeadingGap(t *testing.T) {
+	in := []seriesPoint{pt(1, nil), pt(2, f(2))}
+	out := fillSeries(in, "last")
+	if out[0].Value != nil {
+		t.Fatalf("leading gap has no prior value to carry, want nil, got %v", out[0].Value)
+	}
+}
+
+func TestFillSeriesInterp(t *testing.T) {
+	in := []seriesPoint{pt(1, f(1)), pt(2, nil), pt(3, nil), pt(4, f(4))}
+	out := fillSeries(in, "interp")
+	if out[1].Value == nil || *out[1].Value != 2 {
+		t.Fatalf("expected day 2 interpolated to 2, got %v", out[1].Value)
+	}
+	if out[2].Value == nil || *out[2].Value != 3 {
+		t.Fatalf("expected day 3 interpolated to 3, got %v", out[2].Value)
+	}
+}
+
+func TestFillSeriesInterpLeavesUnboundedGaps(t *testing.T) {
+	in := []seriesPoint{pt(1, nil), pt(2, f(2)), pt(3, nil)}
+	out := fillSeries(in, "interp")
+	if out[0].Value != nil {
+		t.Fatalf("leading gap can't be interpolated, want nil, got %v", out[0].Value)
+	}
+	if out[2].Value != nil {
+		t.Fatalf("trailing gap can't be interpolated, want nil, got %v", out[2].Value)
+	}
+}
+
+func TestAggregateSeriesDailyIsNoOp(t *testing.T) {
+	in := []seriesPoint{pt(1, f(1)), pt(2, f(2))}
+	out := aggregateSeries(in, riksbank.Daily)
+	if len(out) != 2 {
+		t.Fatalf("expected daily aggregation to leave both points, got %d", len(out))
+	}
+}
+
+func TestAggregateSeriesWeeklyKeepsLastObservationPerISOWeek(t *testing.T) {
+	// 2019-01-01 is a Tuesday in ISO week 1, 2019-01-07 is the following
+	// Monday and starts ISO week 2.
+	in := []seriesPoint{pt(1, f(1)), pt(2, f(2)), pt(7, f(7))}
+	out := aggregateSeries(in, riksbank.Weekly)
+	if len(out) != 2 {
+		t.Fatalf("expected 2 weekly buckets, got %d", len(out))
+	}
+	if out[0].Value == nil || *out[0].Value != 2 {
+		t.Fatalf("expected week 1 to carry its last observation (2), got %v", out[0].Value)
+	}
+	if out[1].Value == nil || *out[1].Value != 7 {
+		t.Fatalf("expected week 2 to carry its only observation (7), got %v", out[1].Value)
+	}
+}
+
+func TestAggregateSeriesMonthlySpansShortMonths(t *testing.T) {
+	jan := time.Date(2019, time.January, 31, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	in := []seriesPoint{
+		{Date: jan, Value: f(1)},
+		{Date: feb, Value: f(2)},
+	}
+	out := aggregateSeries(in, riksbank.Monthly)
+	if len(out) != 2 {
+		t.Fatalf("expected January and February to stay in separate buckets, got %d", len(out))
+	}
+}